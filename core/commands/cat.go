@@ -7,8 +7,10 @@ import (
 
 	cmds "github.com/ipfs/go-ipfs-cmds"
 	"github.com/ipfs/go-ipfs-cmds/cmdsutil"
-	core "github.com/ipfs/go-ipfs/core"
+	"github.com/ipfs/go-ipfs/core/coreapi"
+	coreiface "github.com/ipfs/go-ipfs/core/coreapi/interface"
 	coreunix "github.com/ipfs/go-ipfs/core/coreunix"
+	"github.com/ipfs/go-ipfs/path"
 
 	context "context"
 )
@@ -19,11 +21,23 @@ var CatCmd = &cmds.Command{
 	Helptext: cmdsutil.HelpText{
 		Tagline:          "Show IPFS object data.",
 		ShortDescription: "Displays the data contained by an IPFS or IPNS object(s) at the given path.",
+		LongDescription: `
+Displays the data contained by an IPFS or IPNS object(s) at the given
+path.
+
+The path is resolved through the core API, so it can point at any
+IPLD path into the object, not just its root, e.g.
+'/ipfs/<cid>/a/b/c'.
+`,
 	},
 
 	Arguments: []cmdsutil.Argument{
 		cmdsutil.StringArg("ipfs-path", true, true, "The path to the IPFS object(s) to be outputted.").EnableStdin(),
 	},
+	Options: []cmdsutil.Option{
+		cmdsutil.IntOption("offset", "o", "Byte offset to begin reading from."),
+		cmdsutil.IntOption("length", "l", "Maximum number of bytes to read."),
+	},
 	Run: func(req cmds.Request, re cmds.ResponseEmitter) {
 		log.Debugf("cat: RespEm type is %T", re)
 		node, err := req.InvocContext().GetNode()
@@ -39,7 +53,32 @@ var CatCmd = &cmds.Command{
 			}
 		}
 
-		readers, length, err := cat(req.Context(), node, req.Arguments())
+		offsetOpt, _, err := req.Option("offset").Int()
+		if err != nil {
+			re.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		if offsetOpt < 0 {
+			re.SetError(fmt.Errorf("cannot specify negative offset"), cmdsutil.ErrNormal)
+			return
+		}
+
+		lengthOpt, lengthFound, err := req.Option("length").Int()
+		if err != nil {
+			re.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		if !lengthFound {
+			lengthOpt = -1
+		}
+		if lengthOpt < -1 {
+			re.SetError(fmt.Errorf("cannot specify negative length"), cmdsutil.ErrNormal)
+			return
+		}
+
+		api := coreapi.NewCoreAPI(node)
+
+		readers, length, err := cat(req.Context(), api, req.Arguments(), int64(offsetOpt), int64(lengthOpt))
 		log.Debug("cat returned ", readers, length, err)
 
 		if err != nil {
@@ -115,18 +154,54 @@ var CatCmd = &cmds.Command{
 	},
 }
 
-func cat(ctx context.Context, node *core.IpfsNode, paths []string) ([]io.Reader, uint64, error) {
+// cat concatenates the unixfs files found at paths, clipped to the given
+// offset and length. offset/length apply to the logical concatenation of
+// all paths, not to each path individually: offset is decremented by each
+// path's full (unclipped) size until it falls within a path, so a path
+// entirely before the requested offset contributes nothing and the
+// remaining offset carries into the next path; length is decremented as
+// each path is read.
+func cat(ctx context.Context, api coreiface.CoreAPI, paths []string, offset int64, length int64) ([]io.Reader, uint64, error) {
 	readers := make([]io.Reader, 0, len(paths))
-	length := uint64(0)
+	total := uint64(0)
 	for _, fpath := range paths {
+		if length == 0 {
+			break
+		}
+
 		log.Debug("cat.for: adding ", fpath)
-		read, err := coreunix.Cat(ctx, node, fpath)
+		read, err := coreunix.CatRange(ctx, api, path.New(fpath), offset, length)
 		if err != nil {
 			return nil, 0, err
 		}
+
+		offset, length = carryRange(offset, length, read.FullSize(), read.Size())
+
 		readers = append(readers, read)
-		length += uint64(read.Size())
+		total += uint64(read.Size())
 		log.Debug("cat.for: added reader of size", read.Size())
 	}
-	return readers, length, nil
+	return readers, total, nil
+}
+
+// carryRange returns the offset/length cat should apply to the next path,
+// given that the path just read had the given full (unclipped) size and
+// actually consumed consumed bytes of the range. A path whose full size is
+// smaller than the remaining offset contributes nothing, and the leftover
+// offset carries into the next path rather than resetting to 0; resetting
+// it unconditionally would silently truncate any multi-path cat --offset
+// read whose offset exceeded the first path's size.
+func carryRange(offset, length, full, consumed int64) (int64, int64) {
+	if offset >= full {
+		offset -= full
+	} else {
+		offset = 0
+	}
+	if length != -1 {
+		length -= consumed
+		if length < 0 {
+			length = 0
+		}
+	}
+	return offset, length
 }
@@ -0,0 +1,64 @@
+package commands
+
+import "testing"
+
+// TestCarryRange covers the offset/length bookkeeping cat() uses to apply a
+// single --offset/--length range across multiple paths. Resetting offset to
+// 0 after the first path unconditionally would silently truncate reads
+// whenever the requested offset exceeded the first path's size, instead of
+// carrying the remainder into the next path.
+func TestCarryRange(t *testing.T) {
+	cases := []struct {
+		name           string
+		offset, length int64
+		full, consumed int64
+		wantOffset     int64
+		wantLength     int64
+	}{
+		{
+			name: "offset fully consumes path, carries remainder",
+			offset: 150, length: -1,
+			full: 100, consumed: 0,
+			wantOffset: 50, wantLength: -1,
+		},
+		{
+			name: "offset exactly consumes path",
+			offset: 100, length: -1,
+			full: 100, consumed: 0,
+			wantOffset: 0, wantLength: -1,
+		},
+		{
+			name: "offset only partially consumes path, next path starts at 0",
+			offset: 40, length: -1,
+			full: 100, consumed: 60,
+			wantOffset: 0, wantLength: -1,
+		},
+		{
+			name: "length decremented by bytes actually read",
+			offset: 0, length: 30,
+			full: 100, consumed: 30,
+			wantOffset: 0, wantLength: 0,
+		},
+		{
+			name: "length exhausted mid-path doesn't go negative",
+			offset: 0, length: 10,
+			full: 100, consumed: 10,
+			wantOffset: 0, wantLength: 0,
+		},
+		{
+			name: "unbounded length (-1) stays unbounded",
+			offset: 0, length: -1,
+			full: 100, consumed: 100,
+			wantOffset: 0, wantLength: -1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gotOffset, gotLength := carryRange(c.offset, c.length, c.full, c.consumed)
+			if gotOffset != c.wantOffset || gotLength != c.wantLength {
+				t.Fatalf("carryRange(%d, %d, %d, %d) = (%d, %d), want (%d, %d)",
+					c.offset, c.length, c.full, c.consumed, gotOffset, gotLength, c.wantOffset, c.wantLength)
+			}
+		})
+	}
+}
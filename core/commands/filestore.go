@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -11,7 +13,11 @@ import (
 	oldCmds "github.com/ipfs/go-ipfs/commands"
 
 	"github.com/ipfs/go-ipfs/core"
+	"github.com/ipfs/go-ipfs/core/coreapi"
+	coreiface "github.com/ipfs/go-ipfs/core/coreapi/interface"
 	"github.com/ipfs/go-ipfs/filestore"
+	ipfspath "github.com/ipfs/go-ipfs/path"
+	mh "gx/ipfs/QmU9a9NV9RdPNwZQDYd5uKsm6N6dSXnNGMH9vJpeutnDRq/go-multihash"
 	cid "gx/ipfs/QmV5gPoRsjN1Gid3LMdNZTyfCtP2DsvqEbMAmz82RmmiGk/go-cid"
 	//u "gx/ipfs/QmZuY8aV7zbNXVy6DyN9SmnuH3o9nG852F4aTiSBpts8d1/go-ipfs-util"
 )
@@ -26,12 +32,96 @@ var FileStoreCmd = &cmds.Command{
 	OldSubcommands: map[string]*oldCmds.Command{
 		"verify": verifyFileStore,
 		"dups":   dupsFileStore,
+		"rm":     rmFileStore,
 	},
 }
 
+// listResJSON is the NDJSON shape emitted for --enc=json: Status is the
+// stable string form (see filestore.Status.Format), rather than the
+// internal numeric status, so scripts don't need to track status codes
+// across releases. Multihash is the raw base58 multihash backing Hash, so
+// a v0/v1 Cid pair (or different codecs) for the same bytes can be
+// reconciled across rows without every consumer re-decoding the Cid.
+type listResJSON struct {
+	Status    string
+	Hash      string
+	Multihash string `json:",omitempty"`
+	Size      uint64
+	FilePath  string
+	Offset    uint64
+	ErrorMsg  string `json:",omitempty"`
+}
+
+func newListResJSON(r *filestore.ListRes) *listResJSON {
+	var mhStr string
+	if h, err := filestore.MultihashFor(r); err == nil {
+		mhStr = h.B58String()
+	}
+	return &listResJSON{
+		Status:    r.Status.Format(),
+		Hash:      r.Hash,
+		Multihash: mhStr,
+		Size:      r.Size,
+		FilePath:  r.FilePath,
+		Offset:    r.Offset,
+		ErrorMsg:  r.ErrorMsg,
+	}
+}
+
+// listResJSONMarshaler is shared by the oldCmds filestore commands (verify,
+// rm) to stream one NDJSON line per ListRes. Like the Text marshaler it is
+// built alongside, it is invoked once per streamed value, so no buffering
+// of the overall response is needed.
+func listResJSONMarshaler(res oldCmds.Response) (io.Reader, error) {
+	v := unwrapOutput(res.Output())
+	r := v.(*filestore.ListRes)
+	b, err := json.Marshal(newListResJSON(r))
+	if err != nil {
+		return nil, err
+	}
+	b = append(b, '\n')
+	return bytes.NewReader(b), nil
+}
+
+// lsEncoder streams one JSON object per ListRes directly to w as it is
+// emitted, rather than buffering the whole response, so `filestore
+// verify --enc=json | jq` stays usable on multi-terabyte stores. Exit-code
+// differentiation for "some entries were non-ok" is tracked in Run instead
+// of here (see emitListRes), since Run sees every emitted value regardless
+// of which encoding ends up rendering it.
 type lsEncoder struct {
-	errors bool
-	w      io.Writer
+	w   io.Writer
+	enc *json.Encoder
+}
+
+func (e *lsEncoder) Encode(v interface{}) error {
+	r, ok := v.(*filestore.ListRes)
+	if !ok {
+		return fmt.Errorf("lsEncoder: expected *filestore.ListRes, got %T", v)
+	}
+	if e.enc == nil {
+		e.enc = json.NewEncoder(e.w)
+	}
+	return e.enc.Encode(newListResJSON(r))
+}
+
+// emitListRes relays every value from out through re and, once out is
+// drained, flags the response with cmdsutil.ErrClient if any
+// *filestore.ListRes carried an ErrorMsg. Doing this in Run rather than in
+// an encoder or a single PostRun branch means the exit code differs for
+// "some entries were non-ok" under --enc=json as well as the default text
+// output.
+func emitListRes(re cmds.ResponseEmitter, out <-chan interface{}) {
+	var errored bool
+	for v := range out {
+		if r, ok := v.(*filestore.ListRes); ok && r.ErrorMsg != "" {
+			errored = true
+		}
+		re.Emit(v)
+	}
+	if errored {
+		re.SetError("errors while listing some entries", cmdsutil.ErrClient)
+	}
 }
 
 var lsFileStore = &cmds.Command{
@@ -46,38 +136,66 @@ otherwise list all objects.
 The output is:
 
 <hash> <size> <path> <offset>
+
+Use --file-order to sort the output by file path and offset instead of
+by hash. All entries are collected before the first one is printed;
+no backing file is read.
+
+Use --multihash to interpret <obj> as base58-encoded multihashes
+rather than Cids. Every entry sharing that multihash is listed, which
+makes it possible to see a v0/v1 Cid pair for the same underlying
+bytes as one group.
 `,
 	},
 	Arguments: []cmdsutil.Argument{
-		cmdsutil.StringArg("obj", false, true, "Cid of objects to list."),
+		cmdsutil.StringArg("obj", false, true, "Cid (or, with --multihash, multihash) of objects to list."),
+	},
+	Options: []cmdsutil.Option{
+		cmdsutil.BoolOption("file-order", "sort the results by file path and offset"),
+		cmdsutil.BoolOption("multihash", "interpret <obj> as base58 multihashes instead of Cids"),
 	},
 	Run: func(req cmds.Request, re cmds.ResponseEmitter) {
-		_, fs, err := getFilestore(req.InvocContext())
+		n, fs, err := getFilestore(req.InvocContext())
+		if err != nil {
+			re.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		api := coreapi.NewCoreAPI(n)
+		fileOrder, _, err := req.Option("file-order").Bool()
+		if err != nil {
+			re.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		byMultihash, _, err := req.Option("multihash").Bool()
 		if err != nil {
 			re.SetError(err, cmdsutil.ErrNormal)
 			return
 		}
 		args := req.Arguments()
-		if len(args) > 0 {
-			out := perKeyActionToChan(args, func(c *cid.Cid) *filestore.ListRes {
+		if byMultihash {
+			out := multihashActionToChan(req.Context(), args, func(hs []mh.Multihash) [][]*filestore.ListRes {
+				return filestore.ListByMultihashes(fs, hs)
+			})
+			emitListRes(re, out)
+		} else if len(args) > 0 {
+			out := perKeyActionToChan(req.Context(), api, args, func(c *cid.Cid) *filestore.ListRes {
 				return filestore.List(fs, c)
-			}, req.Context())
-
-			for v := range out {
-				re.Emit(v)
-			}
+			})
+			emitListRes(re, out)
 		} else {
-			next, err := filestore.ListAll(fs)
+			var next func() *filestore.ListRes
+			if fileOrder {
+				next, err = filestore.ListAllFileOrder(fs)
+			} else {
+				next, err = filestore.ListAll(fs)
+			}
 			if err != nil {
 				re.SetError(err, cmdsutil.ErrNormal)
 				return
 			}
 
 			out := listResToChan(next, req.Context())
-			for v := range out {
-				log.Debugf("%T", v)
-				re.Emit(v)
-			}
+			emitListRes(re, out)
 		}
 	},
 	PostRun: map[cmds.EncodingType]func(cmds.Request, cmds.ResponseEmitter) cmds.ResponseEmitter{
@@ -87,10 +205,7 @@ The output is:
 			go func() {
 				defer re.Close()
 
-				var (
-					err    error
-					errors bool
-				)
+				var err error
 
 				for err == nil {
 					var v interface{}
@@ -102,7 +217,6 @@ The output is:
 
 					r := v.(*filestore.ListRes)
 					if r.ErrorMsg != "" {
-						errors = true
 						fmt.Fprintf(os.Stderr, "%s\n", r.ErrorMsg)
 					} else {
 						fmt.Fprintf(os.Stdout, "%s\n", r.FormatLong())
@@ -112,20 +226,27 @@ The output is:
 				if err == io.EOF || err.Error() == "EOF" {
 					// all good
 				} else if err == cmds.ErrRcvdError {
+					// Run's emitListRes already set this to ErrClient when
+					// some entries were non-ok, rather than ErrNormal, so
+					// scripts can tell that apart from the command itself
+					// failing by process exit code. Relay it as-is.
 					e := res.Error()
 					re.SetError(e.Message, e.Code)
 				} else {
 					re.SetError(err, cmdsutil.ErrNormal)
 				}
-
-				if errors {
-					re.SetError("errors while displaying some entries", cmdsutil.ErrNormal)
-				}
 			}()
 
 			return re_
 		},
 	},
+	Encoders: cmds.EncoderMap{
+		cmds.JSON: func(req cmds.Request) func(io.Writer) cmds.Encoder {
+			return func(w io.Writer) cmds.Encoder {
+				return &lsEncoder{w: w}
+			}
+		},
+	},
 	Type: filestore.ListRes{},
 }
 
@@ -151,31 +272,64 @@ missing:  <obj> could not be found in the filestore
 ERROR:    internal error, most likely due to a corrupt database
 
 For ERROR entries the error will also be printed to stderr.
+
+Use --file-order to verify in file path and offset order instead of
+hash order. Consecutive entries backed by the same file are verified
+by reading forward through a single open *os.File, turning the scan
+into a mostly-sequential disk workload.
+
+Use --multihash to interpret <obj> as base58-encoded multihashes
+rather than Cids, verifying every entry that shares that multihash.
 `,
 	},
 	Arguments: []cmdsutil.Argument{
-		cmdsutil.StringArg("obj", false, true, "Cid of objects to verify."),
+		cmdsutil.StringArg("obj", false, true, "Cid (or, with --multihash, multihash) of objects to verify."),
+	},
+	Options: []cmdsutil.Option{
+		cmdsutil.BoolOption("file-order", "verify in file order instead of hash order"),
+		cmdsutil.BoolOption("multihash", "interpret <obj> as base58 multihashes instead of Cids"),
 	},
 	Run: func(req oldCmds.Request, res oldCmds.Response) {
-		_, fs, err := getFilestore(req.InvocContext())
+		n, fs, err := getFilestore(req.InvocContext())
+		if err != nil {
+			res.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		api := coreapi.NewCoreAPI(n)
+		fileOrder, _, err := req.Option("file-order").Bool()
+		if err != nil {
+			res.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		byMultihash, _, err := req.Option("multihash").Bool()
 		if err != nil {
 			res.SetError(err, cmdsutil.ErrNormal)
 			return
 		}
 		args := req.Arguments()
-		if len(args) > 0 {
-			out := perKeyActionToChan(args, func(c *cid.Cid) *filestore.ListRes {
+		if byMultihash {
+			out := multihashActionToChan(req.Context(), args, func(hs []mh.Multihash) [][]*filestore.ListRes {
+				return filestore.VerifyByMultihashes(fs, hs)
+			})
+			res.SetOutput(emitListResWithStatus(res, out))
+		} else if len(args) > 0 {
+			out := perKeyActionToChan(req.Context(), api, args, func(c *cid.Cid) *filestore.ListRes {
 				return filestore.Verify(fs, c)
-			}, req.Context())
-			res.SetOutput(out)
+			})
+			res.SetOutput(emitListResWithStatus(res, out))
 		} else {
-			next, err := filestore.VerifyAll(fs)
+			var next func() *filestore.ListRes
+			if fileOrder {
+				next, err = filestore.VerifyAllFileOrder(fs)
+			} else {
+				next, err = filestore.VerifyAll(fs)
+			}
 			if err != nil {
 				res.SetError(err, cmdsutil.ErrNormal)
 				return
 			}
 			out := listResToChan(next, req.Context())
-			res.SetOutput(out)
+			res.SetOutput(emitListResWithStatus(res, out))
 		}
 	},
 	Marshalers: oldCmds.MarshalerMap{
@@ -188,6 +342,7 @@ For ERROR entries the error will also be printed to stderr.
 			fmt.Fprintf(res.Stdout(), "%s %s\n", r.Status.Format(), r.FormatLong())
 			return nil, nil
 		},
+		oldCmds.JSON: listResJSONMarshaler,
 	},
 	Type: filestore.ListRes{},
 }
@@ -195,6 +350,15 @@ For ERROR entries the error will also be printed to stderr.
 var dupsFileStore = &oldCmds.Command{
 	Helptext: cmdsutil.HelpText{
 		Tagline: "List blocks that are both in the filestore and standard block storage.",
+		LongDescription: `
+List blocks that are both in the filestore and standard block
+storage.
+
+Duplicates are detected by multihash equality rather than by Cid
+equality, so the same bytes stored under a v0 Cid in the filestore
+and a v1 Cid in the blockstore (or vice versa) are still reported as
+a duplicate.
+`,
 	},
 	Run: func(req oldCmds.Request, res oldCmds.Response) {
 		_, fs, err := getFilestore(req.InvocContext())
@@ -202,6 +366,11 @@ var dupsFileStore = &oldCmds.Command{
 			res.SetError(err, cmdsutil.ErrNormal)
 			return
 		}
+		mainMhs, err := mainBlockstoreMultihashes(req.Context(), fs)
+		if err != nil {
+			res.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
 		ch, err := fs.FileManager().AllKeysChan(req.Context())
 		if err != nil {
 			res.SetError(err, cmdsutil.ErrNormal)
@@ -213,20 +382,206 @@ var dupsFileStore = &oldCmds.Command{
 
 		go func() {
 			defer close(out)
-			for cid := range ch {
-				have, err := fs.MainBlockstore().Has(cid)
+			for c := range ch {
+				if _, have := mainMhs[string(c.Hash())]; have {
+					out <- &RefWrapper{Ref: c.String()}
+				}
+			}
+		}()
+	},
+	Marshalers: refsMarshallerMap,
+	Type:       RefWrapper{},
+}
+
+// mainBlockstoreMultihashes returns the set of multihashes backing every
+// block in fs.MainBlockstore(), keyed by the raw multihash bytes so that
+// Cids of any version or codec sharing a multihash compare equal. Building
+// this set up front lets dups match on multihash equality without having
+// to guess at which Cid version/codec combinations to probe.
+func mainBlockstoreMultihashes(ctx context.Context, fs *filestore.Filestore) (map[string]struct{}, error) {
+	ch, err := fs.MainBlockstore().AllKeysChan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mhs := make(map[string]struct{})
+	for c := range ch {
+		mhs[string(c.Hash())] = struct{}{}
+	}
+	return mhs, nil
+}
+
+var rmFileStore = &oldCmds.Command{
+	Helptext: cmdsutil.HelpText{
+		Tagline: "Remove objects from filestore.",
+		LongDescription: `
+Remove objects from the filestore.
+
+If one or more <obj> is specified only remove those specific objects,
+otherwise --all must be given along with a status filter.
+
+The output is:
+
+<status> <hash> <size> <path> <offset>
+
+Where <status> is the status the entry had before removal, same as
+the status reported by 'filestore verify'.
+
+With --all, every entry in the filestore is considered for removal.
+Combine --all with --changed and/or --no-file to restrict removal to
+entries whose backing file has changed or gone missing; this first
+runs 'filestore verify' and deletes only the matching entries, which
+is the supported way to reconcile the filestore index after moving or
+editing files on disk.
+
+Use --dry-run to see what would be removed without actually removing
+anything.
+`,
+	},
+	Arguments: []cmdsutil.Argument{
+		cmdsutil.StringArg("obj", false, true, "Cid of objects to remove."),
+	},
+	Options: []cmdsutil.Option{
+		cmdsutil.BoolOption("all", "remove all entries matching the given status filters"),
+		cmdsutil.BoolOption("changed", "when used with --all, remove entries whose backing file has changed"),
+		cmdsutil.BoolOption("no-file", "when used with --all, remove entries whose backing file is missing"),
+		cmdsutil.BoolOption("dry-run", "don't remove anything, just report what would be removed"),
+	},
+	Run: func(req oldCmds.Request, res oldCmds.Response) {
+		n, fs, err := getFilestore(req.InvocContext())
+		if err != nil {
+			res.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		api := coreapi.NewCoreAPI(n)
+		all, _, err := req.Option("all").Bool()
+		if err != nil {
+			res.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		changed, _, err := req.Option("changed").Bool()
+		if err != nil {
+			res.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		noFile, _, err := req.Option("no-file").Bool()
+		if err != nil {
+			res.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		dryRun, _, err := req.Option("dry-run").Bool()
+		if err != nil {
+			res.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		args := req.Arguments()
+
+		if err := validateRmArgs(args, all, changed, noFile); err != nil {
+			res.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+
+		out := make(chan interface{}, 128)
+		res.SetOutput((<-chan interface{})(out))
+
+		rm := func(r *filestore.ListRes) *filestore.ListRes {
+			if !dryRun && r.Status != filestore.StatusOtherError {
+				c, err := cid.Decode(r.Hash)
+				if err != nil {
+					return &filestore.ListRes{
+						Status:   filestore.StatusOtherError,
+						ErrorMsg: fmt.Sprintf("%s: %v", r.Hash, err),
+					}
+				}
+				if err := fs.FileManager().DeleteBlock(c); err != nil {
+					return &filestore.ListRes{
+						Status:   filestore.StatusOtherError,
+						ErrorMsg: fmt.Sprintf("%s: %v", r.Hash, err),
+					}
+				}
+			}
+			return r
+		}
+
+		go func() {
+			defer close(out)
+
+			if all {
+				next, err := filestore.VerifyAll(fs)
 				if err != nil {
-					out <- &RefWrapper{Err: err.Error()}
+					out <- &filestore.ListRes{Status: filestore.StatusOtherError, ErrorMsg: err.Error()}
 					return
 				}
-				if have {
-					out <- &RefWrapper{Ref: cid.String()}
+				for {
+					r := next()
+					if r == nil {
+						return
+					}
+					if !rmMatchesFilter(r, changed, noFile) {
+						continue
+					}
+					select {
+					case out <- rm(r):
+					case <-req.Context().Done():
+						return
+					}
+				}
+			}
+
+			for _, arg := range args {
+				c, err := resolveToCid(req.Context(), api, arg)
+				if err != nil {
+					out <- &filestore.ListRes{
+						Status:   filestore.StatusOtherError,
+						ErrorMsg: fmt.Sprintf("%s: %v", arg, err),
+					}
+					continue
+				}
+				r := filestore.Verify(fs, c)
+				select {
+				case out <- rm(r):
+				case <-req.Context().Done():
+					return
 				}
 			}
 		}()
 	},
-	Marshalers: refsMarshallerMap,
-	Type:       RefWrapper{},
+	Marshalers: oldCmds.MarshalerMap{
+		oldCmds.Text: func(res oldCmds.Response) (io.Reader, error) {
+			v := unwrapOutput(res.Output())
+			r := v.(*filestore.ListRes)
+			if r.Status == filestore.StatusOtherError {
+				fmt.Fprintf(res.Stderr(), "%s\n", r.ErrorMsg)
+			}
+			fmt.Fprintf(res.Stdout(), "%s %s\n", r.Status.Format(), r.FormatLong())
+			return nil, nil
+		},
+		oldCmds.JSON: listResJSONMarshaler,
+	},
+	Type: filestore.ListRes{},
+}
+
+// validateRmArgs checks that args/all/changed/noFile form one of the two
+// supported rm invocations: one or more explicit <obj>, or --all combined
+// with at least one status filter. It returns the error rm should report,
+// or nil if the combination is valid.
+func validateRmArgs(args []string, all, changed, noFile bool) error {
+	if len(args) == 0 && !all {
+		return fmt.Errorf("must specify one or more <obj>, or --all")
+	}
+	if len(args) > 0 && all {
+		return fmt.Errorf("cannot specify both <obj> and --all")
+	}
+	if all && !changed && !noFile {
+		return fmt.Errorf("--all must be given along with --changed and/or --no-file")
+	}
+	return nil
+}
+
+// rmMatchesFilter reports whether r should be removed under --all, given
+// which of --changed/--no-file were passed.
+func rmMatchesFilter(r *filestore.ListRes, changed, noFile bool) bool {
+	return (changed && r.Status == filestore.StatusCorrupt) ||
+		(noFile && r.Status == filestore.StatusFileMissing)
 }
 
 type getNoder interface {
@@ -264,12 +619,102 @@ func listResToChan(next func() *filestore.ListRes, ctx context.Context) <-chan i
 	return out
 }
 
-func perKeyActionToChan(args []string, action func(*cid.Cid) *filestore.ListRes, ctx context.Context) <-chan interface{} {
+// emitListResWithStatus relays every value from in unchanged to the
+// returned channel and, once in is drained, flags res with
+// cmdsutil.ErrClient if any *filestore.ListRes had a non-ok Status or
+// carried an ErrorMsg (--file-order verification reports changed and
+// no-file entries without an ErrorMsg). oldCmds
+// commands have no PostRun hook to do this after the Marshaler has rendered
+// every value, so verifyFileStore wraps its output channel with this
+// instead, giving `ipfs filestore verify` the same "some entries were
+// non-ok" exit code that ls gets from emitListRes, for either Marshaler.
+func emitListResWithStatus(res oldCmds.Response, in <-chan interface{}) <-chan interface{} {
+	out := make(chan interface{}, 128)
+	go func() {
+		defer close(out)
+		var errored bool
+		for v := range in {
+			if r, ok := v.(*filestore.ListRes); ok && (r.Status != filestore.StatusOK || r.ErrorMsg != "") {
+				errored = true
+			}
+			out <- v
+		}
+		if errored {
+			res.SetError(fmt.Errorf("errors while verifying some entries"), cmdsutil.ErrClient)
+		}
+	}()
+	return out
+}
+
+// resolveToCid resolves arg, which may be a bare CID or an /ipfs/<cid>/...
+// style path, to the terminal Cid it refers to. Resolution is attempted
+// through api first so that paths into a DAG are supported; if arg doesn't
+// parse as a path at all (e.g. api is nil, or arg is a bare Cid string) we
+// fall back to decoding arg directly as a Cid. But once arg does parse as a
+// path, a ResolvePath failure is a real resolution error (a missing link, a
+// timeout, ...) and is returned as-is rather than masked by a follow-up
+// cid.Decode that can only fail with a less useful "invalid cid" message.
+func resolveToCid(ctx context.Context, api coreiface.CoreAPI, arg string) (*cid.Cid, error) {
+	if api != nil {
+		if p, err := ipfspath.ParsePath(arg); err == nil {
+			rp, err := api.ResolvePath(ctx, p)
+			if err != nil {
+				return nil, err
+			}
+			return rp.Cid(), nil
+		}
+	}
+	return cid.Decode(arg)
+}
+
+// multihashActionToChan decodes args as base58 multihashes and runs action
+// against all of them in a single batched call, emitting every ListRes the
+// action returns. Unlike perKeyActionToChan, one arg can fan out into
+// several results since more than one Cid (e.g. a v0/v1 pair, or different
+// codecs) can share the same multihash; batching the whole arg list into one
+// action call, rather than calling action once per arg, keeps `--multihash
+// h1 h2 h3` to a single pass over the filestore instead of one pass per
+// hash.
+func multihashActionToChan(ctx context.Context, args []string, action func([]mh.Multihash) [][]*filestore.ListRes) <-chan interface{} {
+	out := make(chan interface{}, 128)
+	go func() {
+		defer close(out)
+
+		hs := make([]mh.Multihash, 0, len(args))
+		for _, arg := range args {
+			h, err := mh.FromB58String(arg)
+			if err != nil {
+				out <- &filestore.ListRes{
+					Status:   filestore.StatusOtherError,
+					ErrorMsg: fmt.Sprintf("%s: %v", arg, err),
+				}
+				continue
+			}
+			hs = append(hs, h)
+		}
+		if len(hs) == 0 {
+			return
+		}
+
+		for _, rs := range action(hs) {
+			for _, r := range rs {
+				select {
+				case out <- r:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+func perKeyActionToChan(ctx context.Context, api coreiface.CoreAPI, args []string, action func(*cid.Cid) *filestore.ListRes) <-chan interface{} {
 	out := make(chan interface{}, 128)
 	go func() {
 		defer close(out)
 		for _, arg := range args {
-			c, err := cid.Decode(arg)
+			c, err := resolveToCid(ctx, api, arg)
 			if err != nil {
 				out <- &filestore.ListRes{
 					Status:   filestore.StatusOtherError,
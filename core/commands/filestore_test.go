@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-ipfs/filestore"
+)
+
+func TestValidateRmArgs(t *testing.T) {
+	cases := []struct {
+		name    string
+		args    []string
+		all     bool
+		changed bool
+		noFile  bool
+		wantErr bool
+	}{
+		{"no args, no --all", nil, false, false, false, true},
+		{"args and --all", []string{"Qm..."}, true, false, false, true},
+		{"bare --all, no filter", nil, true, false, false, true},
+		{"--all --changed", nil, true, true, false, false},
+		{"--all --no-file", nil, true, false, true, false},
+		{"--all --changed --no-file", nil, true, true, true, false},
+		{"explicit args only", []string{"Qm..."}, false, false, false, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateRmArgs(c.args, c.all, c.changed, c.noFile)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("validateRmArgs(%v, %v, %v, %v) = %v, wantErr %v", c.args, c.all, c.changed, c.noFile, err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestRmMatchesFilter(t *testing.T) {
+	cases := []struct {
+		name    string
+		status  filestore.Status
+		changed bool
+		noFile  bool
+		want    bool
+	}{
+		{"ok, no filters matches nothing", filestore.StatusOK, false, false, false},
+		{"corrupt without --changed", filestore.StatusCorrupt, false, true, false},
+		{"corrupt with --changed", filestore.StatusCorrupt, true, false, true},
+		{"missing without --no-file", filestore.StatusFileMissing, true, false, false},
+		{"missing with --no-file", filestore.StatusFileMissing, false, true, true},
+		{"ok with both filters set", filestore.StatusOK, true, true, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := &filestore.ListRes{Status: c.status}
+			if got := rmMatchesFilter(r, c.changed, c.noFile); got != c.want {
+				t.Fatalf("rmMatchesFilter(status=%v, changed=%v, noFile=%v) = %v, want %v", c.status, c.changed, c.noFile, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package coreunix
+
+import (
+	"fmt"
+	"io"
+
+	context "context"
+
+	coreiface "github.com/ipfs/go-ipfs/core/coreapi/interface"
+	ipfspath "github.com/ipfs/go-ipfs/path"
+	uio "github.com/ipfs/go-ipfs/unixfs/io"
+)
+
+// sizeReader is the minimal surface cat() needs from a CatRange result: a
+// reader clipped to the requested range, the clipped size (so callers like
+// the progress bar don't need to re-derive it), and the unixfs file's full,
+// unclipped size, which a multi-path caller needs to know how much of a
+// requested offset this path actually absorbed.
+type sizeReader interface {
+	io.Reader
+	Size() int64
+	FullSize() int64
+}
+
+type limitedSizeReader struct {
+	io.Reader
+	size int64
+	full int64
+}
+
+func (l *limitedSizeReader) Size() int64 {
+	return l.size
+}
+
+func (l *limitedSizeReader) FullSize() int64 {
+	return l.full
+}
+
+// CatRange resolves p through api and returns a reader over the bytes of
+// the underlying unixfs file starting at offset, clipped to length (or to
+// the end of the file if length is -1). It works by seeking the
+// UnixfsAPI's DagReader to offset and wrapping the remainder in an
+// io.LimitReader, so only the requested range is ever read off disk/the
+// network.
+func CatRange(ctx context.Context, api coreiface.CoreAPI, p ipfspath.Path, offset int64, length int64) (sizeReader, error) {
+	r, err := api.Unixfs().Cat(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	dr, ok := r.(uio.DagReader)
+	if !ok {
+		return nil, fmt.Errorf("cat: %s does not support byte ranges", p)
+	}
+
+	size := int64(dr.Size())
+	if offset > size {
+		offset = size
+	}
+	if offset > 0 {
+		if _, err := dr.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	remaining := size - offset
+	if length >= 0 && length < remaining {
+		remaining = length
+	}
+
+	return &limitedSizeReader{
+		Reader: io.LimitReader(dr, remaining),
+		size:   remaining,
+		full:   size,
+	}, nil
+}
@@ -0,0 +1,178 @@
+package filestore
+
+import (
+	"io"
+	"os"
+	"sort"
+
+	mh "gx/ipfs/QmU9a9NV9RdPNwZQDYd5uKsm6N6dSXnNGMH9vJpeutnDRq/go-multihash"
+	cid "gx/ipfs/QmV5gPoRsjN1Gid3LMdNZTyfCtP2DsvqEbMAmz82RmmiGk/go-cid"
+)
+
+// ListAllFileOrder is like ListAll but groups entries by FilePath (sorted by
+// Offset within each group) instead of emitting them in hash order, so a
+// caller that walks the result in order touches each backing file's blocks
+// with increasing offsets.
+func ListAllFileOrder(fs *Filestore) (func() *ListRes, error) {
+	return fileOrderStream(fs, false)
+}
+
+// VerifyAllFileOrder is like VerifyAll but verifies entries grouped by
+// FilePath and sorted by Offset within each group. Consecutive entries
+// backed by the same file are verified by reading forward through a single
+// open *os.File, rather than letting every entry reopen and seek into its
+// backing file the way hash-ordered verification does.
+func VerifyAllFileOrder(fs *Filestore) (func() *ListRes, error) {
+	return fileOrderStream(fs, true)
+}
+
+// fileOrderStream drives both ListAllFileOrder and VerifyAllFileOrder. It
+// makes a single pass over the hash-ordered ListAll stream to group entries
+// by FilePath, then emits each group (sorted by Offset) through a channel.
+//
+// Two independent passes - one to count entries per FilePath, a second to
+// group and flush a path as soon as its count was reached - would avoid
+// holding every entry in memory at once, but would trust both passes to see
+// an identical filestore. Any mutation between them (a concurrent add/rm, or
+// GC, entirely plausible across the long scan of a multi-terabyte store)
+// would leave a path's running count short of its stale total, so that
+// group would be buffered forever and silently dropped. A single
+// pass can't drop rows that way, at the cost of holding the full entry set
+// (metadata only, no file contents) in memory until the scan completes.
+func fileOrderStream(fs *Filestore, verify bool) (func() *ListRes, error) {
+	next, err := ListAll(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]*ListRes)
+	var order []string
+	for {
+		r := next()
+		if r == nil {
+			break
+		}
+		if _, ok := groups[r.FilePath]; !ok {
+			order = append(order, r.FilePath)
+		}
+		groups[r.FilePath] = append(groups[r.FilePath], r)
+	}
+	sort.Strings(order)
+
+	out := make(chan *ListRes, 128)
+	go func() {
+		defer close(out)
+		for _, path := range order {
+			group := groups[path]
+			sort.SliceStable(group, func(i, j int) bool {
+				return group[i].Offset < group[j].Offset
+			})
+			emitGroup(out, group, verify)
+		}
+	}()
+
+	return channelNextFunc(out), nil
+}
+
+// emitGroup sends one path's entries, already sorted by Offset, to out. When
+// verify is set, it verifies each block by reading forward through a single
+// shared *os.File instead of reopening the backing file per entry.
+func emitGroup(out chan<- *ListRes, group []*ListRes, verify bool) {
+	if !verify {
+		for _, r := range group {
+			out <- r
+		}
+		return
+	}
+
+	var f *os.File
+	var curOffset int64 = -1
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	for _, r := range group {
+		if r.Status != StatusOK {
+			out <- r
+			continue
+		}
+
+		if f == nil {
+			opened, err := os.Open(r.FilePath)
+			if err != nil {
+				if os.IsNotExist(err) {
+					out <- &ListRes{Status: StatusFileMissing, Hash: r.Hash, Size: r.Size, FilePath: r.FilePath, Offset: r.Offset}
+				} else {
+					out <- &ListRes{Status: StatusOtherError, ErrorMsg: err.Error(), Hash: r.Hash, Size: r.Size, FilePath: r.FilePath, Offset: r.Offset}
+				}
+				continue
+			}
+			f = opened
+			curOffset = -1
+		}
+
+		if curOffset != int64(r.Offset) {
+			off, err := f.Seek(int64(r.Offset), io.SeekStart)
+			if err != nil {
+				out <- &ListRes{Status: StatusOtherError, ErrorMsg: err.Error(), Hash: r.Hash, Size: r.Size, FilePath: r.FilePath, Offset: r.Offset}
+				f.Close()
+				f = nil
+				continue
+			}
+			curOffset = off
+		}
+
+		buf := make([]byte, r.Size)
+		if _, err := io.ReadFull(f, buf); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				// the file exists but is now shorter than this block
+				out <- &ListRes{Status: StatusCorrupt, Hash: r.Hash, Size: r.Size, FilePath: r.FilePath, Offset: r.Offset}
+			} else {
+				out <- &ListRes{Status: StatusOtherError, ErrorMsg: err.Error(), Hash: r.Hash, Size: r.Size, FilePath: r.FilePath, Offset: r.Offset}
+			}
+			f.Close()
+			f = nil
+			continue
+		}
+		curOffset += int64(r.Size)
+
+		status, err := verifyBlockData(r.Hash, buf)
+		if err != nil {
+			out <- &ListRes{Status: StatusOtherError, ErrorMsg: err.Error(), Hash: r.Hash, Size: r.Size, FilePath: r.FilePath, Offset: r.Offset}
+			continue
+		}
+		out <- &ListRes{Status: status, Hash: r.Hash, Size: r.Size, FilePath: r.FilePath, Offset: r.Offset}
+	}
+}
+
+// channelNextFunc adapts a channel of results into the func() *ListRes
+// iterator shape used throughout this package.
+func channelNextFunc(out <-chan *ListRes) func() *ListRes {
+	return func() *ListRes {
+		r, ok := <-out
+		if !ok {
+			return nil
+		}
+		return r
+	}
+}
+
+// verifyBlockData recomputes the multihash of data and compares it against
+// the multihash encoded in hash, returning StatusOK or StatusCorrupt.
+func verifyBlockData(hash string, data []byte) (Status, error) {
+	c, err := cid.Decode(hash)
+	if err != nil {
+		return StatusOtherError, err
+	}
+	pfx := c.Prefix()
+	sum, err := mh.Sum(data, pfx.MhType, pfx.MhLength)
+	if err != nil {
+		return StatusOtherError, err
+	}
+	if sum.B58String() != c.Hash().B58String() {
+		return StatusCorrupt, nil
+	}
+	return StatusOK, nil
+}
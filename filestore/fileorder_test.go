@@ -0,0 +1,37 @@
+package filestore
+
+import (
+	"testing"
+
+	mh "gx/ipfs/QmU9a9NV9RdPNwZQDYd5uKsm6N6dSXnNGMH9vJpeutnDRq/go-multihash"
+	cid "gx/ipfs/QmV5gPoRsjN1Gid3LMdNZTyfCtP2DsvqEbMAmz82RmmiGk/go-cid"
+)
+
+func TestVerifyBlockData(t *testing.T) {
+	data := []byte("hello filestore")
+	sum, err := mh.Sum(data, mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatalf("mh.Sum: %v", err)
+	}
+	c := cid.NewCidV1(cid.Raw, sum)
+
+	status, err := verifyBlockData(c.String(), data)
+	if err != nil {
+		t.Fatalf("verifyBlockData(matching data): %v", err)
+	}
+	if status != StatusOK {
+		t.Fatalf("verifyBlockData(matching data) = %v, want StatusOK", status)
+	}
+
+	status, err = verifyBlockData(c.String(), []byte("different bytes"))
+	if err != nil {
+		t.Fatalf("verifyBlockData(changed data): %v", err)
+	}
+	if status != StatusCorrupt {
+		t.Fatalf("verifyBlockData(changed data) = %v, want StatusCorrupt", status)
+	}
+
+	if _, err := verifyBlockData("not a cid", data); err == nil {
+		t.Fatalf("verifyBlockData(invalid hash) = nil error, want error")
+	}
+}
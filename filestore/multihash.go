@@ -0,0 +1,83 @@
+package filestore
+
+import (
+	mh "gx/ipfs/QmU9a9NV9RdPNwZQDYd5uKsm6N6dSXnNGMH9vJpeutnDRq/go-multihash"
+	cid "gx/ipfs/QmV5gPoRsjN1Gid3LMdNZTyfCtP2DsvqEbMAmz82RmmiGk/go-cid"
+)
+
+// ListByMultihash returns the ListRes for every Cid in the filestore that
+// shares the multihash h, regardless of Cid version or codec.
+func ListByMultihash(fs *Filestore, h mh.Multihash) []*ListRes {
+	return ListByMultihashes(fs, []mh.Multihash{h})[0]
+}
+
+// VerifyByMultihash is like ListByMultihash but verifies each matching
+// entry instead of just listing it.
+func VerifyByMultihash(fs *Filestore, h mh.Multihash) []*ListRes {
+	return VerifyByMultihashes(fs, []mh.Multihash{h})[0]
+}
+
+// ListByMultihashes is like ListByMultihash but matches a batch of
+// multihashes in a single pass over the filestore, returning one []*ListRes
+// per element of hs (in the same order) rather than rescanning the whole
+// filestore once per hash.
+func ListByMultihashes(fs *Filestore, hs []mh.Multihash) [][]*ListRes {
+	return collectByMultihashes(fs, hs, false)
+}
+
+// VerifyByMultihashes is the batched form of VerifyByMultihash.
+func VerifyByMultihashes(fs *Filestore, hs []mh.Multihash) [][]*ListRes {
+	return collectByMultihashes(fs, hs, true)
+}
+
+func collectByMultihashes(fs *Filestore, hs []mh.Multihash, verify bool) [][]*ListRes {
+	out := make([][]*ListRes, len(hs))
+
+	var next func() *ListRes
+	var err error
+	if verify {
+		next, err = VerifyAll(fs)
+	} else {
+		next, err = ListAll(fs)
+	}
+	if err != nil {
+		errRes := []*ListRes{{Status: StatusOtherError, ErrorMsg: err.Error()}}
+		for i := range out {
+			out[i] = errRes
+		}
+		return out
+	}
+
+	idx := make(map[string]int, len(hs))
+	for i, h := range hs {
+		idx[string(h)] = i
+	}
+
+	for {
+		r := next()
+		if r == nil {
+			break
+		}
+		h, err := MultihashFor(r)
+		if err != nil {
+			continue
+		}
+		if i, ok := idx[string(h)]; ok {
+			out[i] = append(out[i], r)
+		}
+	}
+	return out
+}
+
+// MultihashFor returns the raw multihash backing r, decoded from r.Hash.
+// It's exposed so callers building a scriptable result shape (e.g. the
+// NDJSON output in core/commands) can attach the same multihash that
+// ListByMultihash/VerifyByMultihash match against, instead of re-decoding
+// r.Hash themselves.
+func MultihashFor(r *ListRes) (mh.Multihash, error) {
+	c, err := cid.Decode(r.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return c.Hash(), nil
+}